@@ -0,0 +1,204 @@
+// Copyright (c) 2017 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/brunokenj/atomicswap/cmd/ethatomicswap/chaincfg"
+	"github.com/brunokenj/atomicswap/cmd/ethatomicswap/contract"
+	"github.com/brunokenj/atomicswap/cmd/ethatomicswap/multirpc"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	cli "github.com/urfave/cli/v2"
+)
+
+// signer produces signed transactions for the account it was constructed
+// for, either from a raw private key (-privkey) or an account unlocked from
+// a keystore directory (-keystore/-account/-password).
+type signer interface {
+	address() common.Address
+	transactOpts(chainID *big.Int) (*bind.TransactOpts, error)
+}
+
+// privkeySigner signs with a raw private key given directly on the command
+// line via -privkey.
+type privkeySigner struct {
+	key *ecdsa.PrivateKey
+}
+
+func (s *privkeySigner) address() common.Address {
+	return crypto.PubkeyToAddress(s.key.PublicKey)
+}
+
+func (s *privkeySigner) transactOpts(chainID *big.Int) (*bind.TransactOpts, error) {
+	return bind.NewKeyedTransactorWithChainID(s.key, chainID)
+}
+
+// keystoreSigner signs with an account unlocked from a go-ethereum keystore
+// directory, so submitting a swap transaction no longer requires trusting a
+// raw private key to the command line or environment.
+type keystoreSigner struct {
+	ks      *keystore.KeyStore
+	account accounts.Account
+}
+
+func (s *keystoreSigner) address() common.Address {
+	return s.account.Address
+}
+
+func (s *keystoreSigner) transactOpts(chainID *big.Int) (*bind.TransactOpts, error) {
+	return bind.NewKeyStoreTransactorWithChainID(s.ks, s.account, chainID)
+}
+
+// resolveSigner builds a signer from -privkey or -keystore/-account/-password,
+// whichever was given. -privkey takes priority since it needs no unlocking.
+func resolveSigner(c *cli.Context) (signer, error) {
+	if privkey := c.String("privkey"); privkey != "" {
+		key, err := crypto.HexToECDSA(strings.TrimPrefix(privkey, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid -privkey: %v", err)
+		}
+		return &privkeySigner{key: key}, nil
+	}
+
+	dir := c.String("keystore")
+	acct := c.String("account")
+	if dir == "" || acct == "" {
+		return nil, fmt.Errorf("-privkey, or both -keystore and -account, is required to submit transactions")
+	}
+	passwordFile := c.String("password")
+	if passwordFile == "" {
+		return nil, fmt.Errorf("-password is required to unlock -account")
+	}
+	password, err := os.ReadFile(passwordFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading -password file: %v", err)
+	}
+
+	ks := keystore.NewKeyStore(dir, keystore.StandardScryptN, keystore.StandardScryptP)
+	account, err := ks.Find(accounts.Account{Address: common.HexToAddress(acct)})
+	if err != nil {
+		return nil, fmt.Errorf("account %s not found in keystore %s: %v", acct, dir, err)
+	}
+	if err := ks.Unlock(account, strings.TrimSpace(string(password))); err != nil {
+		return nil, fmt.Errorf("unlocking account %s: %v", acct, err)
+	}
+	return &keystoreSigner{ks: ks, account: account}, nil
+}
+
+// gweiWei is the number of wei in one gwei, used to convert
+// Params.FixedGasPriceGwei into a wei-denominated gas price.
+const gweiWei = 1_000_000_000
+
+// ethBackend bundles the pieces needed to both call into the contract
+// package and submit signed transactions from the active signer.
+type ethBackend struct {
+	client *multirpc.Client
+	signer signer
+}
+
+func dialBackend(c *cli.Context, client *multirpc.Client) (*ethBackend, error) {
+	s, err := resolveSigner(c)
+	if err != nil {
+		return nil, err
+	}
+	return &ethBackend{client: client, signer: s}, nil
+}
+
+func (b *ethBackend) from() common.Address {
+	return b.signer.address()
+}
+
+func (b *ethBackend) transactOpts(ctx context.Context, value *big.Int, gasLimit uint64) (*bind.TransactOpts, error) {
+	chainID, err := b.client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("chain ID: %v", err)
+	}
+	opts, err := b.signer.transactOpts(chainID)
+	if err != nil {
+		return nil, err
+	}
+	opts.Context = ctx
+	opts.Value = value
+	opts.GasLimit = gasLimit
+	if activeChain.GasPricePolicy == chaincfg.GasPriceFixed {
+		opts.GasPrice = new(big.Int).Mul(big.NewInt(activeChain.FixedGasPriceGwei), big.NewInt(gweiWei))
+	}
+	return opts, nil
+}
+
+// swapContract returns a binding to the ETHSwap contract at addr, or deploys
+// a fresh one and returns a binding to it when addr is the zero address.
+func (b *ethBackend) swapContract(ctx context.Context, addr common.Address) (*contract.ETHSwap, common.Address, error) {
+	if addr != (common.Address{}) {
+		c, err := contract.NewETHSwap(addr, b.client)
+		return c, addr, err
+	}
+	return b.deploy(ctx)
+}
+
+// deploy deploys a fresh ETHSwap contract and returns a binding to it.
+func (b *ethBackend) deploy(ctx context.Context) (*contract.ETHSwap, common.Address, error) {
+	opts, err := b.transactOpts(ctx, nil, 0)
+	if err != nil {
+		return nil, common.Address{}, err
+	}
+	deployedAddr, tx, c, err := contract.DeployETHSwap(opts, b.client)
+	if err != nil {
+		return nil, common.Address{}, fmt.Errorf("deploy ETHSwap: %v", err)
+	}
+	fmt.Println("Deployed ETHSwap contract at", deployedAddr.Hex())
+	fmt.Println("Deployment transaction:", tx.Hash().Hex())
+	return c, deployedAddr, nil
+}
+
+// initiate calls initiate or initiateERC20 on swap depending on whether
+// token is the zero address, approving the swap contract to pull amount of
+// token first when it isn't.
+func (b *ethBackend) initiate(ctx context.Context, swap *contract.ETHSwap, contractAddr, participant common.Address, secretHash [32]byte, refundTime, amount *big.Int, token common.Address, gasLimit uint64) (*types.Transaction, error) {
+	if token == (common.Address{}) {
+		opts, err := b.transactOpts(ctx, amount, gasLimit)
+		if err != nil {
+			return nil, err
+		}
+		return swap.Initiate(opts, participant, secretHash, refundTime)
+	}
+
+	erc20, err := contract.NewERC20(token, b.client)
+	if err != nil {
+		return nil, err
+	}
+	approveOpts, err := b.transactOpts(ctx, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	approveTx, err := erc20.Approve(approveOpts, contractAddr, amount)
+	if err != nil {
+		return nil, fmt.Errorf("approve: %v", err)
+	}
+	approveReceipt, err := bind.WaitMined(ctx, b.client, approveTx)
+	if err != nil {
+		return nil, fmt.Errorf("waiting for approve to be mined: %v", err)
+	}
+	if approveReceipt.Status != types.ReceiptStatusSuccessful {
+		return nil, fmt.Errorf("approve transaction %s reverted", approveTx.Hash().Hex())
+	}
+
+	opts, err := b.transactOpts(ctx, nil, gasLimit)
+	if err != nil {
+		return nil, err
+	}
+	return swap.InitiateERC20(opts, token, participant, secretHash, refundTime, amount)
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2017 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package chaincfg holds the per-chain parameters ethatomicswap needs to talk
+// to a given EVM network: its chain ID, a default RPC endpoint, an optional
+// already-deployed ETHSwap contract address, the number of confirmations a
+// swap should wait for, and a gas price policy. Adding support for another
+// EVM chain is a matter of adding an entry here rather than touching the
+// command implementations.
+package chaincfg
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// GasPricePolicy describes how a chain's gas price should be determined.
+type GasPricePolicy int
+
+const (
+	// GasPriceSuggested leaves the gas price unset so that every transaction
+	// takes the connected node's suggested fee (a suggested tip cap on top of
+	// the base fee on EIP-1559 chains, eth_gasPrice otherwise). Appropriate for chains with
+	// volatile, unpredictable fee markets such as Ethereum mainnet.
+	GasPriceSuggested GasPricePolicy = iota
+
+	// GasPriceFixed uses FixedGasPriceGwei unconditionally. Appropriate for
+	// chains with a flat or near-flat minimum gas price, such as most
+	// simnets and some L2s.
+	GasPriceFixed
+)
+
+// Params describes the static parameters of a single EVM chain or network.
+type Params struct {
+	// Name is the value passed to the -chain flag.
+	Name string
+
+	// ChainID is the network's EIP-155 chain ID. The CLI validates this
+	// against the connected node's eth_chainId result before sending any
+	// transaction, so that an operator cannot accidentally broadcast a
+	// mainnet-funded swap against the wrong network.
+	ChainID *big.Int
+
+	// DefaultRPC is used when neither -rpc nor -s is given.
+	DefaultRPC string
+
+	// SwapContract is the address of a well-known, already-deployed ETHSwap
+	// contract on this chain, if one exists. The zero address means no
+	// contract is pre-registered and -contract-address (or a fresh deploy)
+	// must be used instead.
+	SwapContract common.Address
+
+	// Confirmations is the number of block confirmations a swap contract
+	// transaction should wait for before being treated as final.
+	Confirmations uint64
+
+	// GasPricePolicy selects how a transaction's gas price is chosen.
+	GasPricePolicy GasPricePolicy
+
+	// FixedGasPriceGwei is the gas price, in gwei, used when GasPricePolicy
+	// is GasPriceFixed.
+	FixedGasPriceGwei int64
+}
+
+// Params for the supported chains. Keep in sync with the -chain flag usage
+// string in main.go.
+var (
+	EthMainnet = &Params{
+		Name:           "eth-mainnet",
+		ChainID:        big.NewInt(1),
+		DefaultRPC:     "https://ethereum-rpc.publicnode.com",
+		Confirmations:  12,
+		GasPricePolicy: GasPriceSuggested,
+	}
+
+	EthSepolia = &Params{
+		Name:           "eth-sepolia",
+		ChainID:        big.NewInt(11155111),
+		DefaultRPC:     "https://ethereum-sepolia-rpc.publicnode.com",
+		Confirmations:  3,
+		GasPricePolicy: GasPriceSuggested,
+	}
+
+	EthHolesky = &Params{
+		Name:           "eth-holesky",
+		ChainID:        big.NewInt(17000),
+		DefaultRPC:     "https://ethereum-holesky-rpc.publicnode.com",
+		Confirmations:  3,
+		GasPricePolicy: GasPriceSuggested,
+	}
+
+	Polygon = &Params{
+		Name:           "polygon",
+		ChainID:        big.NewInt(137),
+		DefaultRPC:     "https://polygon-rpc.com",
+		Confirmations:  128,
+		GasPricePolicy: GasPriceSuggested,
+	}
+
+	PolygonAmoy = &Params{
+		Name:           "polygon-amoy",
+		ChainID:        big.NewInt(80002),
+		DefaultRPC:     "https://rpc-amoy.polygon.technology",
+		Confirmations:  12,
+		GasPricePolicy: GasPriceSuggested,
+	}
+
+	Simnet = &Params{
+		Name:              "simnet",
+		ChainID:           big.NewInt(42),
+		DefaultRPC:        "http://localhost:8545",
+		Confirmations:     1,
+		GasPricePolicy:    GasPriceFixed,
+		FixedGasPriceGwei: 1,
+	}
+)
+
+// registry maps a -chain flag value to its Params.
+var registry = map[string]*Params{
+	EthMainnet.Name:  EthMainnet,
+	EthSepolia.Name:  EthSepolia,
+	EthHolesky.Name:  EthHolesky,
+	Polygon.Name:     Polygon,
+	PolygonAmoy.Name: PolygonAmoy,
+	Simnet.Name:      Simnet,
+}
+
+// Lookup returns the Params registered under name, or an error listing the
+// supported names if there is no such chain.
+func Lookup(name string) (*Params, error) {
+	params, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown chain %q (supported: %s)", name, supportedNames())
+	}
+	return params, nil
+}
+
+func supportedNames() string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	// Stable, deterministic ordering for error messages and -h output.
+	for i := 1; i < len(names); i++ {
+		for j := i; j > 0 && names[j-1] > names[j]; j-- {
+			names[j-1], names[j] = names[j], names[j-1]
+		}
+	}
+	out := ""
+	for i, name := range names {
+		if i > 0 {
+			out += ", "
+		}
+		out += name
+	}
+	return out
+}
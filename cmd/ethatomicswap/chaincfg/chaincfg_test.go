@@ -0,0 +1,58 @@
+// Copyright (c) 2017 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		name    string
+		chain   string
+		want    *Params
+		wantErr bool
+	}{
+		{name: "eth-mainnet", chain: "eth-mainnet", want: EthMainnet},
+		{name: "eth-sepolia", chain: "eth-sepolia", want: EthSepolia},
+		{name: "eth-holesky", chain: "eth-holesky", want: EthHolesky},
+		{name: "polygon", chain: "polygon", want: Polygon},
+		{name: "polygon-amoy", chain: "polygon-amoy", want: PolygonAmoy},
+		{name: "simnet", chain: "simnet", want: Simnet},
+		{name: "unknown", chain: "eth-nonexistent", wantErr: true},
+		{name: "empty", chain: "", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Lookup(tc.chain)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("Lookup(%q) = %v, want error", tc.chain, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Lookup(%q) returned unexpected error: %v", tc.chain, err)
+			}
+			if got != tc.want {
+				t.Fatalf("Lookup(%q) = %v, want %v", tc.chain, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLookupUnknownChainListsSupportedNamesInOrder(t *testing.T) {
+	_, err := Lookup("does-not-exist")
+	if err == nil {
+		t.Fatal("Lookup(\"does-not-exist\") = nil error, want error")
+	}
+
+	want := "eth-holesky, eth-mainnet, eth-sepolia, polygon, polygon-amoy, simnet"
+	if !strings.Contains(err.Error(), want) {
+		t.Fatalf("error %q does not contain ordered chain list %q", err.Error(), want)
+	}
+}
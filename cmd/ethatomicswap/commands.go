@@ -0,0 +1,439 @@
+// Copyright (c) 2017 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/brunokenj/atomicswap/cmd/ethatomicswap/contract"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	cli "github.com/urfave/cli/v2"
+)
+
+func initiateAction(c *cli.Context) error {
+	if c.NArg() != 2 {
+		return fmt.Errorf("initiate: expected <participant address> <amount>")
+	}
+	cp2Addr, err := parseAddress(c.Args().Get(0), "participant address")
+	if err != nil {
+		return err
+	}
+	token, err := flagToken(c)
+	if err != nil {
+		return err
+	}
+	if err := checkUnitCompatibleWithToken(c, token); err != nil {
+		return err
+	}
+	amount, err := parseAmount(c.Args().Get(1), c.String("unit"))
+	if err != nil {
+		return err
+	}
+
+	backend, err := dialBackend(c, rpcClient)
+	if err != nil {
+		return err
+	}
+	contractAddr, err := flagContractAddress(c)
+	if err != nil {
+		return err
+	}
+	swap, contractAddr, err := backend.swapContract(c.Context, contractAddr)
+	if err != nil {
+		return err
+	}
+
+	secret := make([]byte, secretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return fmt.Errorf("generating secret: %v", err)
+	}
+	secretHash := sha256.Sum256(secret)
+	refundTime := big.NewInt(time.Now().Add(initiatorLockTime).Unix())
+
+	gasLimit, err := flagGasLimit(c, "initiate")
+	if err != nil {
+		return err
+	}
+	tx, err := backend.initiate(c.Context, swap, contractAddr, cp2Addr, secretHash, refundTime, amount, token, gasLimit)
+	if err != nil {
+		return fmt.Errorf("initiate: %v", err)
+	}
+
+	fmt.Println("Secret:     ", common.Bytes2Hex(secret))
+	fmt.Println("Secret hash:", common.Bytes2Hex(secretHash[:]))
+	fmt.Println()
+	fmt.Println("Contract address:    ", contractAddr.Hex())
+	fmt.Println("Contract transaction:", tx.Hash().Hex())
+	fmt.Println("Refund time:         ", time.Unix(refundTime.Int64(), 0).UTC())
+
+	if err := saveSwap(&swapRecord{
+		Chain:       activeChain.Name,
+		Contract:    contractAddr,
+		ContractTx:  tx.Hash(),
+		SecretHash:  common.Bytes2Hex(secretHash[:]),
+		Secret:      common.Bytes2Hex(secret),
+		Participant: cp2Addr,
+		Token:       token,
+		Amount:      amount.String(),
+		RefundTime:  refundTime.Int64(),
+	}); err != nil {
+		fmt.Fprintln(os.Stderr, "warning: could not save swap state:", err)
+	}
+
+	return waitForConfirmations(c.Context, rpcClient, tx.Hash(), "contract transaction")
+}
+
+func participateAction(c *cli.Context) error {
+	if c.NArg() != 3 {
+		return fmt.Errorf("participate: expected <initiator address> <amount> <secret hash>")
+	}
+	cp1Addr, err := parseAddress(c.Args().Get(0), "initiator address")
+	if err != nil {
+		return err
+	}
+	secretHashBytes, err := parseHash32(c.Args().Get(2), "secret hash")
+	if err != nil {
+		return err
+	}
+	token, err := flagToken(c)
+	if err != nil {
+		return err
+	}
+	if err := checkUnitCompatibleWithToken(c, token); err != nil {
+		return err
+	}
+	amount, err := parseAmount(c.Args().Get(1), c.String("unit"))
+	if err != nil {
+		return err
+	}
+
+	backend, err := dialBackend(c, rpcClient)
+	if err != nil {
+		return err
+	}
+	contractAddr, err := flagContractAddress(c)
+	if err != nil {
+		return err
+	}
+	swap, contractAddr, err := backend.swapContract(c.Context, contractAddr)
+	if err != nil {
+		return err
+	}
+
+	var secretHash [32]byte
+	copy(secretHash[:], secretHashBytes)
+	refundTime := big.NewInt(time.Now().Add(participantLockTime).Unix())
+
+	gasLimit, err := flagGasLimit(c, "initiate")
+	if err != nil {
+		return err
+	}
+	tx, err := backend.initiate(c.Context, swap, contractAddr, cp1Addr, secretHash, refundTime, amount, token, gasLimit)
+	if err != nil {
+		return fmt.Errorf("initiate: %v", err)
+	}
+
+	fmt.Println("Contract address:    ", contractAddr.Hex())
+	fmt.Println("Contract transaction:", tx.Hash().Hex())
+	fmt.Println("Refund time:         ", time.Unix(refundTime.Int64(), 0).UTC())
+
+	if err := saveSwap(&swapRecord{
+		Chain:       activeChain.Name,
+		Contract:    contractAddr,
+		ContractTx:  tx.Hash(),
+		SecretHash:  common.Bytes2Hex(secretHash[:]),
+		Participant: cp1Addr,
+		Token:       token,
+		Amount:      amount.String(),
+		RefundTime:  refundTime.Int64(),
+	}); err != nil {
+		fmt.Fprintln(os.Stderr, "warning: could not save swap state:", err)
+	}
+
+	return waitForConfirmations(c.Context, rpcClient, tx.Hash(), "contract transaction")
+}
+
+func redeemAction(c *cli.Context) error {
+	var contractAddr common.Address
+	var secret []byte
+
+	if resume := c.String("resume"); resume != "" {
+		rec, err := loadSwap(resume)
+		if err != nil {
+			return err
+		}
+		if rec.Secret == "" {
+			return fmt.Errorf("saved swap %s has no known secret; pass <contract> <contract transaction> <secret> explicitly", resume)
+		}
+		contractAddr = rec.Contract
+		secret = common.FromHex(rec.Secret)
+	} else {
+		if c.NArg() != 3 {
+			return fmt.Errorf("redeem: expected <contract> <contract transaction> <secret>, or -resume <secret hash>")
+		}
+		addr, err := parseAddress(c.Args().Get(0), "contract")
+		if err != nil {
+			return err
+		}
+		contractTxBytes, err := parseHash32(c.Args().Get(1), "contract transaction")
+		if err != nil {
+			return err
+		}
+		s, err := parseHash32(c.Args().Get(2), "secret")
+		if err != nil {
+			return err
+		}
+		if err := checkSecretMatchesContractTx(c.Context, common.BytesToHash(contractTxBytes), s); err != nil {
+			return err
+		}
+		contractAddr = addr
+		secret = s
+	}
+
+	backend, err := dialBackend(c, rpcClient)
+	if err != nil {
+		return err
+	}
+
+	swap, err := contract.NewETHSwap(contractAddr, backend.client)
+	if err != nil {
+		return err
+	}
+
+	var secretArr [32]byte
+	copy(secretArr[:], secret)
+
+	gasLimit, err := flagGasLimit(c, "redeem")
+	if err != nil {
+		return err
+	}
+	opts, err := backend.transactOpts(c.Context, nil, gasLimit)
+	if err != nil {
+		return err
+	}
+	tx, err := swap.Redeem(opts, secretArr)
+	if err != nil {
+		return fmt.Errorf("redeem: %v", err)
+	}
+
+	fmt.Println("Redemption transaction:", tx.Hash().Hex())
+	return waitForConfirmations(c.Context, rpcClient, tx.Hash(), "redemption transaction")
+}
+
+func extractSecretAction(c *cli.Context) error {
+	if c.NArg() != 2 {
+		return fmt.Errorf("extractsecret: expected <redemption transaction> <secret hash>")
+	}
+	redemptionTxBytes, err := parseHash32(c.Args().Get(0), "redemption transaction")
+	if err != nil {
+		return err
+	}
+	secretHash, err := parseHash32(c.Args().Get(1), "secret hash")
+	if err != nil {
+		return err
+	}
+	redemptionTx := common.BytesToHash(redemptionTxBytes)
+
+	tx, _, err := rpcClient.TransactionByHash(c.Context, redemptionTx)
+	if err != nil {
+		return fmt.Errorf("fetching redemption transaction: %v", err)
+	}
+
+	swapABI, err := contract.ETHSwapMetaData.GetAbi()
+	if err != nil {
+		return err
+	}
+	method, err := swapABI.MethodById(tx.Data())
+	if err != nil {
+		return fmt.Errorf("decoding redemption transaction: %v", err)
+	}
+	if method.Name != "redeem" {
+		return fmt.Errorf("transaction %s does not call redeem()", redemptionTx.Hex())
+	}
+	args, err := method.Inputs.Unpack(tx.Data()[4:])
+	if err != nil {
+		return fmt.Errorf("unpacking redeem args: %v", err)
+	}
+	secret := args[0].([32]byte)
+
+	gotHash := sha256.Sum256(secret[:])
+	if common.Bytes2Hex(gotHash[:]) != common.Bytes2Hex(secretHash) {
+		return fmt.Errorf("secret does not hash to the expected value")
+	}
+
+	fmt.Println("Secret:", common.Bytes2Hex(secret[:]))
+	return nil
+}
+
+// decodeInitiateTx fetches txHash and decodes it as an initiate() or
+// initiateERC20() call, returning the swap parameters it committed to.
+func decodeInitiateTx(ctx context.Context, txHash common.Hash) (participant common.Address, secretHash [32]byte, refundTime, txAmount *big.Int, err error) {
+	tx, _, err := rpcClient.TransactionByHash(ctx, txHash)
+	if err != nil {
+		return participant, secretHash, nil, nil, fmt.Errorf("fetching contract transaction: %v", err)
+	}
+	swapABI, err := contract.ETHSwapMetaData.GetAbi()
+	if err != nil {
+		return participant, secretHash, nil, nil, err
+	}
+	method, err := swapABI.MethodById(tx.Data())
+	if err != nil {
+		return participant, secretHash, nil, nil, fmt.Errorf("decoding contract transaction: %v", err)
+	}
+	if method.Name != "initiate" && method.Name != "initiateERC20" {
+		return participant, secretHash, nil, nil, fmt.Errorf("transaction %s does not call initiate() or initiateERC20()", txHash.Hex())
+	}
+	args, err := method.Inputs.Unpack(tx.Data()[4:])
+	if err != nil {
+		return participant, secretHash, nil, nil, fmt.Errorf("unpacking %s args: %v", method.Name, err)
+	}
+
+	if method.Name == "initiate" {
+		participant = args[0].(common.Address)
+		secretHash = args[1].([32]byte)
+		refundTime = args[2].(*big.Int)
+		txAmount = tx.Value()
+	} else {
+		participant = args[1].(common.Address)
+		secretHash = args[2].([32]byte)
+		refundTime = args[3].(*big.Int)
+		txAmount = args[4].(*big.Int)
+	}
+	return participant, secretHash, refundTime, txAmount, nil
+}
+
+// checkSecretMatchesContractTx verifies that secret actually redeems the
+// swap committed to by the initiate/initiateERC20 call in contractTx,
+// catching a mistyped or unrelated contract transaction before redeem()
+// is ever broadcast.
+func checkSecretMatchesContractTx(ctx context.Context, contractTx common.Hash, secret []byte) error {
+	_, wantHash, _, _, err := decodeInitiateTx(ctx, contractTx)
+	if err != nil {
+		return err
+	}
+	gotHash := sha256.Sum256(secret)
+	if gotHash != wantHash {
+		return fmt.Errorf("secret does not hash to the secret hash committed to by contract transaction %s", contractTx.Hex())
+	}
+	return nil
+}
+
+func auditContractAction(c *cli.Context) error {
+	if c.NArg() != 2 {
+		return fmt.Errorf("auditcontract: expected <contract> <contract transaction>")
+	}
+	contractAddr, err := parseAddress(c.Args().Get(0), "contract")
+	if err != nil {
+		return err
+	}
+	contractTxBytes, err := parseHash32(c.Args().Get(1), "contract transaction")
+	if err != nil {
+		return err
+	}
+	contractTx := common.BytesToHash(contractTxBytes)
+
+	swap, err := contract.NewETHSwap(contractAddr, rpcClient)
+	if err != nil {
+		return err
+	}
+
+	participant, secretHash, refundTime, txAmount, err := decodeInitiateTx(c.Context, contractTx)
+	if err != nil {
+		return err
+	}
+
+	state, err := swap.Swaps(&bind.CallOpts{Context: c.Context}, secretHash)
+	if err != nil {
+		return fmt.Errorf("reading contract state: %v", err)
+	}
+	if state.Initiator == (common.Address{}) {
+		return fmt.Errorf("contract does not recognize secret hash %x; initiate transaction may not be mined yet", secretHash)
+	}
+
+	fmt.Println("Contract address:", contractAddr.Hex())
+	fmt.Println("Secret hash:      ", common.Bytes2Hex(secretHash[:]))
+	fmt.Println("Initiator:        ", state.Initiator.Hex())
+	fmt.Println("Participant:      ", participant.Hex(), "==", state.Participant.Hex())
+	if err := printAmount(c.Context, rpcClient, state.Token, state.Amount); err != nil {
+		return err
+	}
+	fmt.Println("Refund time:      ", time.Unix(refundTime.Int64(), 0).UTC(), "==", time.Unix(state.RefundTime.Int64(), 0).UTC())
+	fmt.Println("Redeemed:         ", state.Redeemed)
+	fmt.Println("Refunded:         ", state.Refunded)
+
+	if participant.Hex() != state.Participant.Hex() ||
+		refundTime.Cmp(state.RefundTime) != 0 ||
+		txAmount.Cmp(state.Amount) != 0 {
+		return fmt.Errorf("contract state does not match initiate transaction")
+	}
+	return nil
+}
+
+func deployAction(c *cli.Context) error {
+	backend, err := dialBackend(c, rpcClient)
+	if err != nil {
+		return err
+	}
+	_, _, err = backend.deploy(c.Context)
+	return err
+}
+
+// printAmount prints a contract-locked amount, resolving the token's symbol
+// and decimals to show a human-readable value when token is not the zero
+// (native ETH) address.
+func printAmount(ctx context.Context, backend bind.ContractBackend, token common.Address, amount *big.Int) error {
+	if token == (common.Address{}) {
+		fmt.Println("Amount:           ", amount, "wei")
+		return nil
+	}
+
+	erc20, err := contract.NewERC20(token, backend)
+	if err != nil {
+		return err
+	}
+	opts := &bind.CallOpts{Context: ctx}
+	symbol, err := erc20.Symbol(opts)
+	if err != nil {
+		return fmt.Errorf("reading token symbol: %v", err)
+	}
+	decimals, err := erc20.Decimals(opts)
+	if err != nil {
+		return fmt.Errorf("reading token decimals: %v", err)
+	}
+
+	fmt.Println("Token:            ", token.Hex(), fmt.Sprintf("(%s, %d decimals)", symbol, decimals))
+	fmt.Println("Amount:           ", humanAmount(amount, decimals), symbol)
+	return nil
+}
+
+// humanAmount renders amount (in the token's smallest unit) as a decimal
+// string with decimals digits after the point.
+func humanAmount(amount *big.Int, decimals uint8) string {
+	s := amount.String()
+	neg := strings.HasPrefix(s, "-")
+	if neg {
+		s = s[1:]
+	}
+	for len(s) <= int(decimals) {
+		s = "0" + s
+	}
+	whole, frac := s[:len(s)-int(decimals)], s[len(s)-int(decimals):]
+	out := whole
+	if decimals > 0 {
+		out += "." + frac
+	}
+	if neg {
+		out = "-" + out
+	}
+	return out
+}
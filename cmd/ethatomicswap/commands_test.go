@@ -0,0 +1,35 @@
+// Copyright (c) 2017 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestHumanAmount(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   *big.Int
+		decimals uint8
+		want     string
+	}{
+		{name: "zero decimals", amount: big.NewInt(12345), decimals: 0, want: "12345"},
+		{name: "usdc-style 6 decimals", amount: big.NewInt(5000000), decimals: 6, want: "5.000000"},
+		{name: "leading zero fraction", amount: big.NewInt(5), decimals: 6, want: "0.000005"},
+		{name: "zero amount", amount: big.NewInt(0), decimals: 18, want: "0.000000000000000000"},
+		{name: "large 18-decimal value", amount: new(big.Int).Mul(big.NewInt(3), big.NewInt(1e18)), decimals: 18, want: "3.000000000000000000"},
+		{name: "negative amount", amount: big.NewInt(-5), decimals: 2, want: "-0.05"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := humanAmount(tc.amount, tc.decimals)
+			if got != tc.want {
+				t.Fatalf("humanAmount(%s, %d) = %q, want %q", tc.amount, tc.decimals, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2017 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	cli "github.com/urfave/cli/v2"
+)
+
+// gasLimits is the per-chain gas limits measured by getgas, persisted so
+// initiate/participate/redeem/refund can default to them instead of 0
+// (auto-estimate) the next time the same chain is used.
+type gasLimits struct {
+	Initiate uint64 `json:"initiate"`
+	Redeem   uint64 `json:"redeem"`
+	Refund   uint64 `json:"refund"`
+}
+
+// gasLimitsPath returns ~/.ethatomicswap/gaslimits/<chain>.json, creating
+// its directory if necessary.
+func gasLimitsPath(chain string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("resolving home directory: %v", err)
+	}
+	dir := filepath.Join(home, ".ethatomicswap", "gaslimits")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, chain+".json"), nil
+}
+
+// saveGasLimits persists the suggested gas limits getgas measured for chain.
+func saveGasLimits(chain string, limits gasLimits) error {
+	path, err := gasLimitsPath(chain)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(limits, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// loadGasLimits reads back the gas limits getgas saved for chain, if any.
+// A missing file is not an error: it just means getgas has never been run
+// against this chain, so the zero value (auto-estimate) is returned.
+func loadGasLimits(chain string) (gasLimits, error) {
+	path, err := gasLimitsPath(chain)
+	if err != nil {
+		return gasLimits{}, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return gasLimits{}, nil
+	}
+	if err != nil {
+		return gasLimits{}, err
+	}
+	var limits gasLimits
+	if err := json.Unmarshal(data, &limits); err != nil {
+		return gasLimits{}, fmt.Errorf("corrupt gas limits %s: %v", path, err)
+	}
+	return limits, nil
+}
+
+// flagGasLimit resolves the gas limit for op ("initiate", "redeem", or
+// "refund"): the -gas-limit-<op> flag if given, falling back to the value
+// getgas last measured for the active chain, or 0 (auto-estimate) if getgas
+// has never been run against it.
+func flagGasLimit(c *cli.Context, op string) (uint64, error) {
+	if limit := c.Uint64("gas-limit-" + op); limit != 0 {
+		return limit, nil
+	}
+	limits, err := loadGasLimits(activeChain.Name)
+	if err != nil {
+		return 0, fmt.Errorf("loading saved gas limits: %v", err)
+	}
+	switch op {
+	case "initiate":
+		return limits.Initiate, nil
+	case "redeem":
+		return limits.Redeem, nil
+	case "refund":
+		return limits.Refund, nil
+	default:
+		return 0, fmt.Errorf("flagGasLimit: unknown op %q", op)
+	}
+}
@@ -0,0 +1,219 @@
+// Copyright (c) 2017 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/brunokenj/atomicswap/cmd/ethatomicswap/contract"
+	"github.com/brunokenj/atomicswap/cmd/ethatomicswap/multirpc"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	cli "github.com/urfave/cli/v2"
+)
+
+// getgasRefundDelay is the refund timer used for the refund half of each
+// cycle. It is deliberately short so getgas finishes in a reasonable time;
+// it is not representative of a real swap's lock time.
+const getgasRefundDelay = 15 * time.Second
+
+// getGasAction benchmarks the real gas cost of initiate, redeem, and refund
+// on the connected chain by running them against a freshly deployed contract
+// (or the one -contract-address or the chain's SwapContract names), so
+// that operators of unfamiliar or newly added EVM chains (see chaincfg) get
+// measured numbers instead of guessing. The suggested limits are saved (see
+// gaslimits.go) and picked up automatically by initiate/participate/redeem
+// the next time -gas-limit-initiate/-gas-limit-redeem is left at 0. It is
+// meant to be run against a testnet or simnet, since every cycle spends real
+// gas and the refund half of each pair waits out a short refund timer.
+func getGasAction(c *cli.Context) error {
+	cycles := c.Int("cycles")
+	if cycles < 1 {
+		return fmt.Errorf("-cycles must be at least 1")
+	}
+
+	backend, err := dialBackend(c, rpcClient)
+	if err != nil {
+		return err
+	}
+
+	contractAddr, err := flagContractAddress(c)
+	if err != nil {
+		return err
+	}
+	swap, contractAddr, err := backend.swapContract(c.Context, contractAddr)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Benchmarking against ETHSwap at", contractAddr.Hex())
+
+	var initiateGas, redeemGas, refundGas []uint64
+
+	for i := 0; i < cycles; i++ {
+		secret := make([]byte, secretSize)
+		if _, err := rand.Read(secret); err != nil {
+			return fmt.Errorf("generating secret: %v", err)
+		}
+		secretHash := sha256.Sum256(secret)
+
+		if i%2 == 0 {
+			gas, err := initiateRedeemCycle(c, backend, swap, secretHash, secret)
+			if err != nil {
+				return fmt.Errorf("cycle %d (initiate/redeem): %v", i, err)
+			}
+			initiateGas = append(initiateGas, gas[0])
+			redeemGas = append(redeemGas, gas[1])
+		} else {
+			gas, err := initiateRefundCycle(c, backend, swap, secretHash)
+			if err != nil {
+				return fmt.Errorf("cycle %d (initiate/refund): %v", i, err)
+			}
+			initiateGas = append(initiateGas, gas[0])
+			refundGas = append(refundGas, gas[1])
+		}
+		fmt.Printf("cycle %d/%d done\n", i+1, cycles)
+	}
+
+	initiateSuggested := printGasStats("initiate", initiateGas)
+	redeemSuggested := printGasStats("redeem", redeemGas)
+	refundSuggested := printGasStats("refund", refundGas)
+
+	limits, err := loadGasLimits(activeChain.Name)
+	if err != nil {
+		return fmt.Errorf("loading saved gas limits: %v", err)
+	}
+	// Only overwrite an op's saved limit when this run actually sampled it,
+	// so -cycles 1 (which runs no refund cycle) doesn't zero out a previous
+	// refund measurement.
+	if initiateSuggested != 0 {
+		limits.Initiate = initiateSuggested
+	}
+	if redeemSuggested != 0 {
+		limits.Redeem = redeemSuggested
+	}
+	if refundSuggested != 0 {
+		limits.Refund = refundSuggested
+	}
+	if err := saveGasLimits(activeChain.Name, limits); err != nil {
+		return fmt.Errorf("saving measured gas limits: %v", err)
+	}
+	fmt.Printf("saved measured gas limits for %s; initiate/participate/redeem will use them as the default -gas-limit-* when the flag is left at 0\n", activeChain.Name)
+	return nil
+}
+
+// initiateRedeemCycle initiates a 1 wei swap to our own account and
+// immediately redeems it, returning [initiateGas, redeemGas].
+func initiateRedeemCycle(c *cli.Context, backend *ethBackend, swap *contract.ETHSwap, secretHash [32]byte, secret []byte) ([2]uint64, error) {
+	var gas [2]uint64
+	ctx := c.Context
+
+	refundTime := big.NewInt(time.Now().Add(time.Hour).Unix())
+	initiateTx, err := backend.initiate(ctx, swap, common.Address{}, backend.from(), secretHash, refundTime, big.NewInt(1), common.Address{}, c.Uint64("gas-limit-initiate"))
+	if err != nil {
+		return gas, fmt.Errorf("initiate: %v", err)
+	}
+	initiateReceipt, err := waitReceipt(ctx, rpcClient, initiateTx.Hash())
+	if err != nil {
+		return gas, err
+	}
+	gas[0] = initiateReceipt.GasUsed
+
+	var secretArr [32]byte
+	copy(secretArr[:], secret)
+	opts, err := backend.transactOpts(ctx, nil, c.Uint64("gas-limit-redeem"))
+	if err != nil {
+		return gas, err
+	}
+	redeemTx, err := swap.Redeem(opts, secretArr)
+	if err != nil {
+		return gas, fmt.Errorf("redeem: %v", err)
+	}
+	redeemReceipt, err := waitReceipt(ctx, rpcClient, redeemTx.Hash())
+	if err != nil {
+		return gas, err
+	}
+	gas[1] = redeemReceipt.GasUsed
+
+	return gas, nil
+}
+
+// initiateRefundCycle initiates a 1 wei swap with a short refund timer, waits
+// it out, and refunds, returning [initiateGas, refundGas].
+func initiateRefundCycle(c *cli.Context, backend *ethBackend, swap *contract.ETHSwap, secretHash [32]byte) ([2]uint64, error) {
+	var gas [2]uint64
+	ctx := c.Context
+
+	refundAt := time.Now().Add(getgasRefundDelay)
+	refundTime := big.NewInt(refundAt.Unix())
+	initiateTx, err := backend.initiate(ctx, swap, common.Address{}, backend.from(), secretHash, refundTime, big.NewInt(1), common.Address{}, c.Uint64("gas-limit-initiate"))
+	if err != nil {
+		return gas, fmt.Errorf("initiate: %v", err)
+	}
+	initiateReceipt, err := waitReceipt(ctx, rpcClient, initiateTx.Hash())
+	if err != nil {
+		return gas, err
+	}
+	gas[0] = initiateReceipt.GasUsed
+
+	if wait := time.Until(refundAt); wait > 0 {
+		time.Sleep(wait + time.Second)
+	}
+
+	opts, err := backend.transactOpts(ctx, nil, c.Uint64("gas-limit-refund"))
+	if err != nil {
+		return gas, err
+	}
+	refundTx, err := swap.Refund(opts, secretHash)
+	if err != nil {
+		return gas, fmt.Errorf("refund: %v", err)
+	}
+	refundReceipt, err := waitReceipt(ctx, rpcClient, refundTx.Hash())
+	if err != nil {
+		return gas, err
+	}
+	gas[1] = refundReceipt.GasUsed
+
+	return gas, nil
+}
+
+func waitReceipt(ctx context.Context, client *multirpc.Client, txHash common.Hash) (*types.Receipt, error) {
+	for {
+		receipt, err := client.TransactionReceipt(ctx, txHash)
+		if err == nil {
+			return receipt, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// printGasStats prints min/median/max/suggested for op and returns the
+// suggested gas limit, or 0 (leaving auto-estimate in place) if samples is
+// empty.
+func printGasStats(op string, samples []uint64) uint64 {
+	if len(samples) == 0 {
+		fmt.Printf("%-10s no samples\n", op)
+		return 0
+	}
+	sorted := append([]uint64(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	min := sorted[0]
+	max := sorted[len(sorted)-1]
+	median := sorted[len(sorted)/2]
+	suggested := max + max/5 // 20% safety margin
+
+	fmt.Printf("%-10s min=%-10d median=%-10d max=%-10d suggested GasLimit=%d\n", op, min, median, max, suggested)
+	return suggested
+}
@@ -6,7 +6,6 @@ package main
 
 import (
 	"context"
-	"flag"
 	"fmt"
 	"math/big"
 	"net"
@@ -14,29 +13,35 @@ import (
 	"strings"
 	"time"
 
+	"github.com/brunokenj/atomicswap/cmd/ethatomicswap/chaincfg"
+	"github.com/brunokenj/atomicswap/cmd/ethatomicswap/multirpc"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/types"
-	rpc "github.com/ethereum/go-ethereum/rpc"
+	cli "github.com/urfave/cli/v2"
 )
 
-const verify = true
-
 const secretSize = 32
 
-// Type Block
-type Block struct {
-	Number string
-	Hash   string
-}
-
-var (
-	flagset     = flag.NewFlagSet("", flag.ExitOnError)
-	connectFlag = flagset.String("s", "localhost", "host[:port] of Geth RPC server")
-	rpcuserFlag = flagset.String("rpcuser", "", "username for wallet RPC authentication")
-	rpcpassFlag = flagset.String("rpcpass", "", "password for wallet RPC authentication")
-	testnetFlag = flagset.Bool("testnet", false, "use testnet ropsten network")
+// initiatorLockTime and participantLockTime are the default refund delays
+// used when this party is, respectively, the initiator or the participant of
+// the swap. The participant is given less time than the initiator so that the
+// initiator always has a chance to see the secret and redeem before their own
+// refund time arrives.
+const (
+	initiatorLockTime   = 48 * time.Hour
+	participantLockTime = 24 * time.Hour
 )
 
+// activeChain is resolved from -chain in Before and consulted by every
+// command for its chain ID, default RPC endpoint, and gas policy.
+var activeChain *chaincfg.Params
+
+// rpcClient is dialed in Before against the resolved RPC endpoint(s) for the
+// active chain, and shared by every command.
+var rpcClient *multirpc.Client
+
+// minConfirmations is resolved from -min-confirmations in Before.
+var minConfirmations uint64
+
 // There are two directions that the atomic swap can be performed, as the
 // initiator can be on either chain.  This tool only deals with creating the
 // Bitcoin transactions for these swaps.  A second tool should be used for the
@@ -59,152 +64,277 @@ var (
 //     - must verify H(S) in contract is hash of known secret
 //   cp2 redeems btc with S
 
-func init() {
-	flagset.Usage = func() {
-		fmt.Println("Usage: ethatomicswap [flags] cmd [cmd args]")
-		fmt.Println()
-		fmt.Println("Commands:")
-		fmt.Println("  initiate <participant address> <amount>")
-		fmt.Println("  participate <initiator address> <amount> <secret hash>")
-		fmt.Println("  redeem <contract> <contract transaction> <secret>")
-		fmt.Println("  extractsecret <redemption transaction> <secret hash>")
-		fmt.Println("  auditcontract <contract> <contract transaction>")
-		fmt.Println()
-		fmt.Println("Flags:")
-		flagset.PrintDefaults()
-	}
-}
-
-type command interface {
-	runCommand(context.Context, *rpc.Client) error
-}
-
-type initiateCmd struct {
-}
+func main() {
+	app := &cli.App{
+		Name:  "ethatomicswap",
+		Usage: "perform the Ethereum side of an atomic swap",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "chain",
+				Value: chaincfg.EthMainnet.Name,
+				Usage: "EVM chain to operate on (eth-mainnet, eth-sepolia, eth-holesky, polygon, polygon-amoy, simnet)",
+			},
+			&cli.StringFlag{
+				Name:  "rpc",
+				Usage: "comma-separated list of JSON-RPC endpoints to dial with automatic failover (defaults to -s, or the active chain's public endpoint)",
+			},
+			&cli.StringFlag{
+				Name:  "s",
+				Usage: "host[:port] of Geth RPC server (defaults to the -chain's public endpoint)",
+			},
+			&cli.StringFlag{
+				Name:   "contract-address",
+				Usage:  "address of an already-deployed ETHSwap contract to reuse instead of deploying a new one",
+				Action: validateAddressFlag("contract-address"),
+			},
+			&cli.StringFlag{
+				Name:   "token",
+				Usage:  "ERC-20 token contract address; if set, initiate/participate lock this token instead of native ETH",
+				Action: validateAddressFlag("token"),
+			},
+			&cli.StringFlag{
+				Name:  "unit",
+				Value: "wei",
+				Usage: "unit the <amount> argument is denominated in: wei, gwei, or ether",
+			},
+			&cli.Uint64Flag{
+				Name:  "min-confirmations",
+				Usage: "block confirmations to require, across a quorum of -rpc endpoints, before a submitted transaction is considered final (0 = use the active chain's default)",
+			},
+			&cli.Uint64Flag{
+				Name:  "gas-limit-initiate",
+				Usage: "gas limit for initiate/participate transactions (0 = use the limit getgas last measured for this chain, or auto-estimate if getgas has never been run)",
+			},
+			&cli.Uint64Flag{
+				Name:  "gas-limit-redeem",
+				Usage: "gas limit for redeem transactions (0 = use the limit getgas last measured for this chain, or auto-estimate if getgas has never been run)",
+			},
+			&cli.Uint64Flag{
+				Name:  "gas-limit-refund",
+				Usage: "gas limit for refund transactions (0 = auto-estimate)",
+			},
+			&cli.StringFlag{
+				Name:  "privkey",
+				Usage: "hex-encoded private key used to sign transactions",
+			},
+			&cli.StringFlag{
+				Name:  "keystore",
+				Usage: "path to a go-ethereum keystore directory; used with -account and -password instead of -privkey",
+			},
+			&cli.StringFlag{
+				Name:   "account",
+				Usage:  "hex address of the -keystore account to sign with",
+				Action: validateAddressFlag("account"),
+			},
+			&cli.StringFlag{
+				Name:  "password",
+				Usage: "path to a file holding the -account passphrase",
+			},
+		},
+		Before: func(c *cli.Context) error {
+			chain, err := chaincfg.Lookup(c.String("chain"))
+			if err != nil {
+				return err
+			}
+			activeChain = chain
+			minConfirmations = c.Uint64("min-confirmations")
 
-type participateCmd struct {
-	cp1Addr    common.Address
-	amount     *big.Int
-	secretHash []byte
-}
+			endpoints, err := rpcEndpoints(c)
+			if err != nil {
+				return err
+			}
+			client, err := multirpc.Dial(c.Context, endpoints)
+			if err != nil {
+				return fmt.Errorf("rpc connect: %v", err)
+			}
+			if err := validateChainID(c.Context, client, activeChain); err != nil {
+				client.Close()
+				return err
+			}
+			rpcClient = client
+			return nil
+		},
+		After: func(c *cli.Context) error {
+			if rpcClient != nil {
+				rpcClient.Close()
+			}
+			return nil
+		},
+		Commands: []*cli.Command{
+			{
+				Name:      "initiate",
+				Usage:     "initiate a new swap, locking funds for a participant",
+				ArgsUsage: "<participant address> <amount>",
+				Action:    initiateAction,
+			},
+			{
+				Name:      "participate",
+				Usage:     "participate in a swap initiated by a counterparty",
+				ArgsUsage: "<initiator address> <amount> <secret hash>",
+				Action:    participateAction,
+			},
+			{
+				Name:      "redeem",
+				Usage:     "redeem a swap contract with its secret",
+				ArgsUsage: "<contract> <contract transaction> <secret>",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "resume",
+						Usage: "secret hash of a swap saved by initiate/participate; resumes without re-entering <contract>/<contract transaction>/<secret>",
+					},
+				},
+				Action: redeemAction,
+			},
+			{
+				Name:      "extractsecret",
+				Usage:     "extract the secret from a redemption transaction",
+				ArgsUsage: "<redemption transaction> <secret hash>",
+				Action:    extractSecretAction,
+			},
+			{
+				Name:      "auditcontract",
+				Usage:     "audit a swap contract's on-chain state against its initiate transaction",
+				ArgsUsage: "<contract> <contract transaction>",
+				Action:    auditContractAction,
+			},
+			{
+				Name:   "deploy",
+				Usage:  "deploy a fresh ETHSwap contract and print its address",
+				Action: deployAction,
+			},
+			{
+				Name:  "getgas",
+				Usage: "benchmark initiate/redeem/refund gas on the connected chain",
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:  "cycles",
+						Value: 5,
+						Usage: "number of initiate/redeem and initiate/refund cycles to run",
+					},
+				},
+				Action: getGasAction,
+			},
+		},
+	}
 
-type redeemCmd struct {
-	contract   []byte
-	contractTx *types.Transaction
-	secret     []byte
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 }
 
-type extractSecretCmd struct {
-	redemptionTx *types.Transaction
-	secretHash   []byte
+// validateAddressFlag returns a StringFlag Action that rejects a non-empty
+// value which isn't a well-formed hex address.
+func validateAddressFlag(name string) func(*cli.Context, string) error {
+	return func(c *cli.Context, v string) error {
+		if v != "" && !common.IsHexAddress(v) {
+			return fmt.Errorf("invalid -%s: %q is not a hex address", name, v)
+		}
+		return nil
+	}
 }
 
-type auditContractCmd struct {
-	contract   []byte
-	contractTx *types.Transaction
+func parseAddress(s, what string) (common.Address, error) {
+	if !common.IsHexAddress(s) {
+		return common.Address{}, fmt.Errorf("invalid %s: %q", what, s)
+	}
+	return common.HexToAddress(s), nil
 }
 
-func main() {
-	err, showUsage := run()
-	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
+// parseAmount parses s as a decimal amount denominated in unit (wei, gwei,
+// or ether) and returns the equivalent number of wei.
+func parseAmount(s, unit string) (*big.Int, error) {
+	amount, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount: %q", s)
 	}
-	if showUsage {
-		flagset.Usage()
-	}
-	if err != nil || showUsage {
-		os.Exit(1)
+	switch unit {
+	case "", "wei":
+	case "gwei":
+		amount.Mul(amount, big.NewInt(1e9))
+	case "ether":
+		amount.Mul(amount, big.NewInt(1e18))
+	default:
+		return nil, fmt.Errorf("invalid -unit %q: must be wei, gwei, or ether", unit)
 	}
+	return amount, nil
 }
 
-func run() (err error, showUsage bool) {
-	flagset.Parse(os.Args[1:])
-	args := flagset.Args()
-	if len(args) == 0 {
-		return nil, true
-	}
-	cmdArgs := 0
-	switch args[0] {
-	case "initiate":
-		cmdArgs = 2
-	case "participate":
-		cmdArgs = 3
-	case "redeem":
-		cmdArgs = 3
-	case "extractsecret":
-		cmdArgs = 2
-	case "auditcontract":
-		cmdArgs = 2
-	default:
-		return fmt.Errorf("unknown command %v", args[0]), true
+// checkUnitCompatibleWithToken rejects an ETH-denominated -unit (gwei,
+// ether) when -token is set: <amount> is always in the token's own smallest
+// unit, and gwei/ether's hard-coded 9/18 decimal multipliers only happen to
+// match wei/ether for 18-decimal tokens, silently corrupting the amount for
+// any other token (e.g. 6-decimal USDC).
+func checkUnitCompatibleWithToken(c *cli.Context, token common.Address) error {
+	unit := c.String("unit")
+	if token != (common.Address{}) && unit != "" && unit != "wei" {
+		return fmt.Errorf("-unit must be wei (or omitted) when -token is set; <amount> is in the token's own smallest unit, not ETH-denominated")
 	}
-	nArgs := checkCmdArgLength(args[1:], cmdArgs)
-	flagset.Parse(args[1+nArgs:])
-	if nArgs < cmdArgs {
-		return fmt.Errorf("%s: too few arguments", args[0]), true
-	}
-	if flagset.NArg() != 0 {
-		return fmt.Errorf("unexpected argument: %s", flagset.Arg(0)), true
-	}
-
-	var cmd command
-	switch args[0] {
-	case "initiate":
-		cmd = &initiateCmd{}
-
-	case "participate":
-
-	case "redeem":
-
-	case "extractsecret":
-
-	case "auditcontract":
+	return nil
+}
 
+func parseHash32(s, what string) ([]byte, error) {
+	b := common.FromHex(s)
+	if len(b) != 32 {
+		return nil, fmt.Errorf("invalid %s: %q must be 32 bytes", what, s)
 	}
+	return b, nil
+}
 
-	connect, err := normalizeAddress(*connectFlag, walletPort("mainnet"))
-	if err != nil {
-		return fmt.Errorf("geth server address: %v", err), true
+// flagContractAddress resolves the contract address to reuse, if any:
+// -contract-address takes priority, falling back to the active chain's
+// well-known SwapContract. The zero address tells the caller to deploy.
+func flagContractAddress(c *cli.Context) (common.Address, error) {
+	if addr := c.String("contract-address"); addr != "" {
+		return parseAddress(addr, "contract-address")
 	}
+	return activeChain.SwapContract, nil
+}
 
-	client, err := rpc.Dial(connect)
-	if err != nil {
-		return fmt.Errorf("rpc connect: %v", err), false
+// flagToken resolves -token, if set. The zero address means the swap locks
+// native ETH rather than an ERC-20 token.
+func flagToken(c *cli.Context) (common.Address, error) {
+	if addr := c.String("token"); addr != "" {
+		return parseAddress(addr, "token")
 	}
-	defer func() {
-		client.Close()
-	}()
-
-	err = cmd.runCommand(context.Background(), client)
-	return err, false
+	return common.Address{}, nil
 }
 
-func checkCmdArgLength(args []string, required int) (nArgs int) {
-	if len(args) < required {
-		return 0
-	}
-	for i, arg := range args[:required] {
-		if len(arg) != 1 && strings.HasPrefix(arg, "-") {
-			return i
+// rpcEndpoints resolves the list of JSON-RPC endpoints to dial: -rpc takes
+// priority as a comma-separated list, falling back to the single -s
+// endpoint, falling back to the active chain's public endpoint.
+func rpcEndpoints(c *cli.Context) ([]string, error) {
+	var servers []string
+	switch {
+	case c.String("rpc") != "":
+		for _, s := range strings.Split(c.String("rpc"), ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				servers = append(servers, s)
+			}
 		}
+	case c.String("s") != "":
+		servers = []string{c.String("s")}
+	default:
+		servers = []string{activeChain.DefaultRPC}
 	}
-	return required
-}
 
-func (cmd *initiateCmd) runCommand(ctx context.Context, c *rpc.Client) error {
-	var lastBlock Block
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-	if err := c.CallContext(ctx, &lastBlock, "eth_getBlockByNumber", "latest", false); err != nil {
-		println("can't get latest block:", err)
-		return err
+	endpoints := make([]string, len(servers))
+	for i, s := range servers {
+		endpoint, err := normalizeAddress(s, walletPort())
+		if err != nil {
+			return nil, fmt.Errorf("geth server address: %v", err)
+		}
+		endpoints[i] = endpoint
 	}
-	fmt.Println(lastBlock.Number)
-	fmt.Println(lastBlock.Hash)
-	return nil
+	return endpoints, nil
 }
 
 func normalizeAddress(addr string, defaultPort string) (hostport string, err error) {
+	for _, scheme := range []string{"http://", "https://", "ws://", "wss://"} {
+		if strings.HasPrefix(addr, scheme) {
+			return addr, nil
+		}
+	}
 	host, port, origErr := net.SplitHostPort(addr)
 	if origErr == nil {
 		return net.JoinHostPort(host, port), nil
@@ -217,13 +347,49 @@ func normalizeAddress(addr string, defaultPort string) (hostport string, err err
 	return "http://" + addr, nil
 }
 
-func walletPort(params string) string {
-	switch params {
-	case "testnet":
-		return "8545"
-	case "mainnet":
-		return "8545"
-	default:
-		return "8545"
+// waitForConfirmations blocks until a quorum of RPC endpoints agree txHash
+// has reached the required number of confirmations, so a transient lagging
+// or dishonest provider can't make a swap look final when it isn't. The
+// confirmation target is -min-confirmations if set (non-zero), else the
+// active chain's policy; a resulting target of 0 skips the wait entirely.
+func waitForConfirmations(ctx context.Context, c *multirpc.Client, txHash common.Hash, label string) error {
+	confirmations := activeChain.Confirmations
+	if minConfirmations > 0 {
+		confirmations = minConfirmations
+	}
+	if confirmations == 0 {
+		return nil
 	}
+	quorum := c.ProviderCount()/2 + 1
+	fmt.Printf("Waiting for %d confirmation(s) of the %s across a quorum of %d RPC endpoint(s)...\n",
+		confirmations, label, quorum)
+	receipt, err := c.WaitMinedQuorum(ctx, txHash, confirmations, quorum)
+	if err != nil {
+		return fmt.Errorf("waiting for %s to confirm: %v", label, err)
+	}
+	fmt.Printf("Confirmed %s in block %s\n", label, receipt.BlockNumber)
+	return nil
+}
+
+// validateChainID confirms the connected node reports the chain ID expected
+// by -chain, so a misconfigured RPC endpoint can't cause a swap to be
+// broadcast on the wrong network.
+func validateChainID(ctx context.Context, c *multirpc.Client, params *chaincfg.Params) error {
+	var hexChainID string
+	if err := c.CallContext(ctx, &hexChainID, "eth_chainId"); err != nil {
+		return fmt.Errorf("eth_chainId: %v", err)
+	}
+	gotChainID, ok := new(big.Int).SetString(strings.TrimPrefix(hexChainID, "0x"), 16)
+	if !ok {
+		return fmt.Errorf("eth_chainId: unparsable result %q", hexChainID)
+	}
+	if gotChainID.Cmp(params.ChainID) != 0 {
+		return fmt.Errorf("connected node is on chain ID %s, but -chain %s expects %s",
+			gotChainID, params.Name, params.ChainID)
+	}
+	return nil
+}
+
+func walletPort() string {
+	return "8545"
 }
@@ -0,0 +1,46 @@
+// Copyright (c) 2017 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  string
+		unit    string
+		want    *big.Int
+		wantErr bool
+	}{
+		{name: "wei default unit", amount: "12345", unit: "", want: big.NewInt(12345)},
+		{name: "wei explicit unit", amount: "1", unit: "wei", want: big.NewInt(1)},
+		{name: "gwei", amount: "2", unit: "gwei", want: big.NewInt(2e9)},
+		{name: "ether", amount: "3", unit: "ether", want: new(big.Int).Mul(big.NewInt(3), big.NewInt(1e18))},
+		{name: "zero", amount: "0", unit: "ether", want: big.NewInt(0)},
+		{name: "invalid amount", amount: "not-a-number", unit: "wei", wantErr: true},
+		{name: "invalid unit", amount: "1", unit: "satoshi", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseAmount(tc.amount, tc.unit)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("parseAmount(%q, %q) = %v, want error", tc.amount, tc.unit, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseAmount(%q, %q) returned unexpected error: %v", tc.amount, tc.unit, err)
+			}
+			if got.Cmp(tc.want) != 0 {
+				t.Fatalf("parseAmount(%q, %q) = %s, want %s", tc.amount, tc.unit, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,422 @@
+// Copyright (c) 2017 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package multirpc dials a list of EVM JSON-RPC endpoints and presents them
+// as a single bind.ContractBackend that transparently retries a failing,
+// rate-limited, or lagging provider against the next one in the list. A
+// single rpc.Dial against one host dies the moment that host is flaky; a
+// multirpc.Client keeps going as long as one provider in the list is healthy,
+// which matters for a tool whose whole job is not missing a refund window.
+package multirpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// maxBlocksBehind is how far a provider's last-seen tip may lag the best tip
+// seen across all providers before it is treated as unhealthy and skipped.
+const maxBlocksBehind = 3
+
+// staleAfter is how long a provider's cached tip is trusted before a health
+// refresh re-checks it.
+const staleAfter = 20 * time.Second
+
+// provider tracks one endpoint's connection and recent health.
+type provider struct {
+	endpoint string
+	rpc      *rpc.Client
+	eth      *ethclient.Client
+
+	mu        sync.Mutex
+	latency   time.Duration
+	lastBlock uint64
+	checkedAt time.Time
+	healthy   bool
+}
+
+// Client is a bind.ContractBackend backed by multiple JSON-RPC endpoints.
+// Every call is attempted against providers in order of preference (lowest
+// latency first, among those not lagging the best-seen tip), falling
+// through to the next provider on a network error, an HTTP 429 or 5xx
+// response, or a timeout.
+type Client struct {
+	mu        sync.Mutex
+	providers []*provider
+}
+
+// Dial connects to every endpoint in endpoints (comma-separated host[:port]
+// or URL values are both accepted by the caller; Dial itself expects an
+// already-split slice). At least one endpoint must dial successfully.
+func Dial(ctx context.Context, endpoints []string) (*Client, error) {
+	if len(endpoints) == 0 {
+		return nil, errors.New("multirpc: no endpoints given")
+	}
+
+	c := &Client{}
+	var dialErrs []string
+	for _, endpoint := range endpoints {
+		rc, err := rpc.DialContext(ctx, endpoint)
+		if err != nil {
+			dialErrs = append(dialErrs, fmt.Sprintf("%s: %v", endpoint, err))
+			continue
+		}
+		c.providers = append(c.providers, &provider{
+			endpoint: endpoint,
+			rpc:      rc,
+			eth:      ethclient.NewClient(rc),
+			healthy:  true,
+		})
+	}
+	if len(c.providers) == 0 {
+		return nil, fmt.Errorf("multirpc: could not dial any endpoint: %s", strings.Join(dialErrs, "; "))
+	}
+
+	c.refreshHealth(ctx)
+	return c, nil
+}
+
+// Close closes every underlying provider connection.
+func (c *Client) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, p := range c.providers {
+		p.rpc.Close()
+	}
+}
+
+// refreshHealth polls eth_blockNumber on every provider to update its
+// latency and last-seen tip, and marks providers that error out unhealthy.
+func (c *Client) refreshHealth(ctx context.Context) {
+	c.mu.Lock()
+	providers := append([]*provider(nil), c.providers...)
+	c.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, p := range providers {
+		p := p
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			reqCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			defer cancel()
+			block, err := p.eth.BlockNumber(reqCtx)
+
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.checkedAt = time.Now()
+			if err != nil {
+				p.healthy = false
+				return
+			}
+			p.latency = time.Since(start)
+			p.lastBlock = block
+			p.healthy = true
+		}()
+	}
+	wg.Wait()
+}
+
+// orderedProviders returns providers sorted best-first: healthy providers
+// whose tip isn't more than maxBlocksBehind behind the best-seen tip, sorted
+// by latency, followed by everything else as a last resort.
+func (c *Client) orderedProviders(ctx context.Context) []*provider {
+	c.mu.Lock()
+	providers := append([]*provider(nil), c.providers...)
+	c.mu.Unlock()
+
+	needsRefresh := false
+	var bestBlock uint64
+	for _, p := range providers {
+		p.mu.Lock()
+		if time.Since(p.checkedAt) > staleAfter {
+			needsRefresh = true
+		}
+		if p.healthy && p.lastBlock > bestBlock {
+			bestBlock = p.lastBlock
+		}
+		p.mu.Unlock()
+	}
+	if needsRefresh {
+		c.refreshHealth(ctx)
+		bestBlock = 0
+		for _, p := range providers {
+			p.mu.Lock()
+			if p.healthy && p.lastBlock > bestBlock {
+				bestBlock = p.lastBlock
+			}
+			p.mu.Unlock()
+		}
+	}
+
+	good := make([]*provider, 0, len(providers))
+	rest := make([]*provider, 0, len(providers))
+	for _, p := range providers {
+		p.mu.Lock()
+		behind := bestBlock > 0 && bestBlock-p.lastBlock > maxBlocksBehind
+		ok := p.healthy && !behind
+		p.mu.Unlock()
+		if ok {
+			good = append(good, p)
+		} else {
+			rest = append(rest, p)
+		}
+	}
+	sort.Slice(good, func(i, j int) bool {
+		good[i].mu.Lock()
+		good[j].mu.Lock()
+		defer good[i].mu.Unlock()
+		defer good[j].mu.Unlock()
+		return good[i].latency < good[j].latency
+	})
+	return append(good, rest...)
+}
+
+// isRetryable reports whether err is the kind of transient failure (network
+// error, rate limiting, timeout) that should trigger trying the next
+// provider rather than failing the call outright.
+func isRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	// Prefer the structured HTTP status code go-ethereum attaches to a
+	// non-2xx response over sniffing it out of the error text.
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusTooManyRequests || httpErr.StatusCode >= http.StatusInternalServerError
+	}
+	// Fall back to substring matching for providers that don't surface a
+	// structured error (plain connection failures, truncated responses).
+	msg := err.Error()
+	return strings.Contains(msg, "429") ||
+		strings.Contains(msg, "too many requests") ||
+		strings.Contains(msg, "connection refused") ||
+		strings.Contains(msg, "EOF")
+}
+
+// withFailover tries fn against each provider in preference order, returning
+// the first success. Non-retryable errors are returned immediately.
+func withFailover[T any](ctx context.Context, c *Client, fn func(*provider) (T, error)) (T, error) {
+	var zero T
+	var lastErr error
+	providers := c.orderedProviders(ctx)
+	if len(providers) == 0 {
+		return zero, errors.New("multirpc: no providers available")
+	}
+	for _, p := range providers {
+		v, err := fn(p)
+		if err == nil {
+			return v, nil
+		}
+		lastErr = err
+		if !isRetryable(err) {
+			return zero, err
+		}
+		p.mu.Lock()
+		p.healthy = false
+		p.mu.Unlock()
+	}
+	return zero, fmt.Errorf("multirpc: all providers failed, last error: %w", lastErr)
+}
+
+// The methods below implement bind.ContractBackend (bind.ContractCaller +
+// bind.ContractTransactor + bind.ContractFilterer) plus a handful of
+// convenience methods (ChainID, BlockNumber, TransactionByHash,
+// TransactionReceipt) that the CLI needs directly.
+
+func (c *Client) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	return withFailover(ctx, c, func(p *provider) ([]byte, error) { return p.eth.CodeAt(ctx, account, blockNumber) })
+}
+
+func (c *Client) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return withFailover(ctx, c, func(p *provider) ([]byte, error) { return p.eth.CallContract(ctx, call, blockNumber) })
+}
+
+func (c *Client) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return withFailover(ctx, c, func(p *provider) (*types.Header, error) { return p.eth.HeaderByNumber(ctx, number) })
+}
+
+func (c *Client) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	return withFailover(ctx, c, func(p *provider) ([]byte, error) { return p.eth.PendingCodeAt(ctx, account) })
+}
+
+func (c *Client) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return withFailover(ctx, c, func(p *provider) (uint64, error) { return p.eth.PendingNonceAt(ctx, account) })
+}
+
+func (c *Client) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return withFailover(ctx, c, func(p *provider) (*big.Int, error) { return p.eth.SuggestGasPrice(ctx) })
+}
+
+func (c *Client) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return withFailover(ctx, c, func(p *provider) (*big.Int, error) { return p.eth.SuggestGasTipCap(ctx) })
+}
+
+func (c *Client) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	return withFailover(ctx, c, func(p *provider) (uint64, error) { return p.eth.EstimateGas(ctx, call) })
+}
+
+// SendTransaction broadcasts to every healthy provider rather than just the
+// preferred one, so a single slow or dishonest provider can't silently
+// swallow the transaction. Providers currently marked unhealthy are skipped.
+func (c *Client) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	c.mu.Lock()
+	providers := append([]*provider(nil), c.providers...)
+	c.mu.Unlock()
+
+	var lastErr error
+	sent := 0
+	for _, p := range providers {
+		p.mu.Lock()
+		healthy := p.healthy
+		p.mu.Unlock()
+		if !healthy {
+			continue
+		}
+		if err := p.eth.SendTransaction(ctx, tx); err != nil {
+			lastErr = err
+			continue
+		}
+		sent++
+	}
+	if sent == 0 {
+		if lastErr == nil {
+			lastErr = errors.New("no healthy providers")
+		}
+		return fmt.Errorf("multirpc: failed to broadcast to any provider: %w", lastErr)
+	}
+	return nil
+}
+
+func (c *Client) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	return withFailover(ctx, c, func(p *provider) ([]types.Log, error) { return p.eth.FilterLogs(ctx, query) })
+}
+
+func (c *Client) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	return withFailover(ctx, c, func(p *provider) (ethereum.Subscription, error) {
+		return p.eth.SubscribeFilterLogs(ctx, query, ch)
+	})
+}
+
+func (c *Client) ChainID(ctx context.Context) (*big.Int, error) {
+	return withFailover(ctx, c, func(p *provider) (*big.Int, error) { return p.eth.ChainID(ctx) })
+}
+
+// CallContext performs a raw JSON-RPC call against a healthy provider,
+// failing over to the next one on a retryable error.
+func (c *Client) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	_, err := withFailover(ctx, c, func(p *provider) (struct{}, error) {
+		return struct{}{}, p.rpc.CallContext(ctx, result, method, args...)
+	})
+	return err
+}
+
+// BatchCallContext performs a batch of raw JSON-RPC calls against a single
+// healthy provider, failing over to the next one on a retryable error.
+func (c *Client) BatchCallContext(ctx context.Context, b []rpc.BatchElem) error {
+	_, err := withFailover(ctx, c, func(p *provider) (struct{}, error) {
+		return struct{}{}, p.rpc.BatchCallContext(ctx, b)
+	})
+	return err
+}
+
+// ProviderCount returns the number of endpoints this client was dialed
+// with, healthy or not. Callers use it to size a quorum for WaitMinedQuorum.
+func (c *Client) ProviderCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.providers)
+}
+
+func (c *Client) BlockNumber(ctx context.Context) (uint64, error) {
+	return withFailover(ctx, c, func(p *provider) (uint64, error) { return p.eth.BlockNumber(ctx) })
+}
+
+func (c *Client) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	type result struct {
+		tx      *types.Transaction
+		pending bool
+	}
+	r, err := withFailover(ctx, c, func(p *provider) (result, error) {
+		tx, pending, err := p.eth.TransactionByHash(ctx, hash)
+		return result{tx, pending}, err
+	})
+	return r.tx, r.pending, err
+}
+
+func (c *Client) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return withFailover(ctx, c, func(p *provider) (*types.Receipt, error) { return p.eth.TransactionReceipt(ctx, txHash) })
+}
+
+// WaitMinedQuorum blocks until at least quorum distinct healthy providers
+// agree that tx has been mined with minConfirmations blocks on top of it,
+// returning the receipt once confirmed. This is the
+// "-min-confirmations invariant" guarding against a single provider
+// reporting a transaction mined when the rest of the network hasn't
+// converged on it yet, which matters most right before a refund deadline.
+func (c *Client) WaitMinedQuorum(ctx context.Context, txHash common.Hash, minConfirmations uint64, quorum int) (*types.Receipt, error) {
+	if quorum < 1 {
+		quorum = 1
+	}
+	for {
+		c.mu.Lock()
+		providers := append([]*provider(nil), c.providers...)
+		c.mu.Unlock()
+
+		var receipt *types.Receipt
+		agree := 0
+		for _, p := range providers {
+			p.mu.Lock()
+			healthy := p.healthy
+			p.mu.Unlock()
+			if !healthy {
+				continue
+			}
+			tip, err := p.eth.BlockNumber(ctx)
+			if err != nil {
+				continue
+			}
+			rcpt, err := p.eth.TransactionReceipt(ctx, txHash)
+			if err != nil {
+				continue
+			}
+			if rcpt.BlockNumber == nil || tip < rcpt.BlockNumber.Uint64()+minConfirmations {
+				continue
+			}
+			agree++
+			receipt = rcpt
+		}
+		if agree >= quorum {
+			return receipt, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
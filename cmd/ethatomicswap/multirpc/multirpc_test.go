@@ -0,0 +1,98 @@
+// Copyright (c) 2017 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package multirpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// fakeNetError implements net.Error without depending on any real network
+// condition, so isRetryable's errors.As(..., *net.Error) branch is
+// exercisable without dialing anything.
+type fakeNetError struct{}
+
+func (fakeNetError) Error() string   { return "fake net error" }
+func (fakeNetError) Timeout() bool   { return true }
+func (fakeNetError) Temporary() bool { return true }
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "deadline exceeded", err: context.DeadlineExceeded, want: true},
+		{name: "wrapped deadline exceeded", err: fmt.Errorf("rpc call: %w", context.DeadlineExceeded), want: true},
+		{name: "net.Error", err: fakeNetError{}, want: true},
+		{name: "http 429", err: errors.New("429 Too Many Requests"), want: true},
+		{name: "too many requests text", err: errors.New("provider says too many requests"), want: true},
+		{name: "connection refused", err: errors.New("dial tcp: connection refused"), want: true},
+		{name: "EOF", err: io.EOF, want: true},
+		{name: "http 429 status", err: rpc.HTTPError{StatusCode: 429, Status: "429 Too Many Requests"}, want: true},
+		{name: "http 503 status", err: rpc.HTTPError{StatusCode: 503, Status: "503 Service Unavailable"}, want: true},
+		{name: "http 400 status", err: rpc.HTTPError{StatusCode: 400, Status: "400 Bad Request"}, want: false},
+		{name: "unrelated error", err: errors.New("invalid argument"), want: false},
+		{name: "revert reason", err: errors.New("execution reverted: ETHSwap: already redeemed"), want: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isRetryable(tc.err); got != tc.want {
+				t.Fatalf("isRetryable(%v) = %v, want %v", tc.err, got, tc.want)
+			}
+		})
+	}
+}
+
+// newTestProvider builds a provider with checkedAt set to now, so
+// orderedProviders sees it as fresh and skips refreshHealth (which would
+// otherwise try to dial p.eth, which is nil in these tests).
+func newTestProvider(endpoint string, healthy bool, lastBlock uint64, latency time.Duration) *provider {
+	return &provider{
+		endpoint:  endpoint,
+		healthy:   healthy,
+		lastBlock: lastBlock,
+		latency:   latency,
+		checkedAt: time.Now(),
+	}
+}
+
+func TestOrderedProvidersSortsHealthyByLatencyAndDefersUnhealthy(t *testing.T) {
+	slow := newTestProvider("slow", true, 100, 200*time.Millisecond)
+	fast := newTestProvider("fast", true, 100, 10*time.Millisecond)
+	unhealthy := newTestProvider("unhealthy", false, 100, time.Millisecond)
+	lagging := newTestProvider("lagging", true, 100-maxBlocksBehind-1, time.Millisecond)
+
+	c := &Client{providers: []*provider{slow, unhealthy, fast, lagging}}
+	ordered := c.orderedProviders(context.Background())
+
+	if len(ordered) != 4 {
+		t.Fatalf("orderedProviders returned %d providers, want 4", len(ordered))
+	}
+	if ordered[0] != fast || ordered[1] != slow {
+		t.Fatalf("orderedProviders = %v, want [fast, slow, ...] first", providerEndpoints(ordered))
+	}
+	for _, p := range ordered[2:] {
+		if p != unhealthy && p != lagging {
+			t.Fatalf("unexpected provider %q ranked ahead of the unhealthy/lagging ones", p.endpoint)
+		}
+	}
+}
+
+func providerEndpoints(providers []*provider) []string {
+	endpoints := make([]string, len(providers))
+	for i, p := range providers {
+		endpoints[i] = p.endpoint
+	}
+	return endpoints
+}
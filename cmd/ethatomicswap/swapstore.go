@@ -0,0 +1,82 @@
+// Copyright (c) 2017 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// swapRecord is the state of one swap persisted by initiate/participate,
+// keyed by its secret hash, so that redeem can resume days later without the
+// caller re-entering the contract address, contract transaction, or secret.
+type swapRecord struct {
+	Chain       string         `json:"chain"`
+	Contract    common.Address `json:"contract"`
+	ContractTx  common.Hash    `json:"contractTx"`
+	SecretHash  string         `json:"secretHash"`
+	Secret      string         `json:"secret,omitempty"` // empty on the participant side until redeem reveals it
+	Participant common.Address `json:"participant"`
+	Token       common.Address `json:"token"`
+	Amount      string         `json:"amount"` // decimal, in the asset's smallest unit
+	RefundTime  int64          `json:"refundTime"`
+}
+
+// swapStoreDir returns ~/.ethatomicswap/swaps, creating it if necessary.
+func swapStoreDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("resolving home directory: %v", err)
+	}
+	dir := filepath.Join(home, ".ethatomicswap", "swaps")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+func swapRecordPath(secretHash string) (string, error) {
+	dir, err := swapStoreDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, secretHash+".json"), nil
+}
+
+// saveSwap persists rec so a later redeem can resume the swap without the
+// caller re-entering every parameter.
+func saveSwap(rec *swapRecord) error {
+	path, err := swapRecordPath(rec.SecretHash)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// loadSwap reads back the swap record saved under secretHash by initiate or
+// participate.
+func loadSwap(secretHash string) (*swapRecord, error) {
+	path, err := swapRecordPath(secretHash)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("no saved swap for secret hash %s: %v", secretHash, err)
+	}
+	var rec swapRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("corrupt swap record %s: %v", path, err)
+	}
+	return &rec, nil
+}